@@ -2,12 +2,16 @@ package remote
 
 import (
 	"context"
+	"sync"
 	"time"
 
 	"github.com/docker/buildx/driver"
+	"github.com/docker/buildx/util/bklog"
 	"github.com/docker/buildx/util/progress"
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/util/tracing/detect"
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/backoff"
 )
@@ -16,6 +20,13 @@ type Driver struct {
 	factory driver.Factory
 	driver.InitConfig
 	*tlsOpts
+
+	// ClientOpts, when set, is appended to the dial options used for every
+	// (re-)dial, for injecting test fakes.
+	ClientOpts func() []client.ClientOpt
+
+	lazyOnce sync.Once
+	lazy     *LazyClient
 }
 
 type tlsOpts struct {
@@ -23,25 +34,32 @@ type tlsOpts struct {
 	caCert     string
 	cert       string
 	key        string
+
+	// reloadInterval bounds how long a dialed connection's TLS config is
+	// reused before the cert/key/CA files are re-read from disk. Zero
+	// re-reads on every handshake.
+	reloadInterval time.Duration
 }
 
-func (d *Driver) Bootstrap(ctx context.Context, l progress.Logger) error {
-	c, err := d.Client(ctx)
-	if err != nil {
-		return err
+// WithReloadInterval sets tlsOpts.reloadInterval. Zero (the default)
+// re-reads the CA/cert/key files on every handshake.
+func WithReloadInterval(d time.Duration) func(*Driver) {
+	return func(drv *Driver) {
+		if drv.tlsOpts == nil {
+			drv.tlsOpts = &tlsOpts{}
+		}
+		drv.tlsOpts.reloadInterval = d
 	}
-	return c.Wait(ctx)
 }
 
-func (d *Driver) Info(ctx context.Context) (*driver.Info, error) {
-	c, err := d.Client(ctx)
-	if err != nil {
-		return &driver.Info{
-			Status: driver.Inactive,
-		}, nil
-	}
+func (d *Driver) Bootstrap(ctx context.Context, l progress.Logger) error {
+	d.log(ctx).Debug("bootstrapping remote driver")
+	return d.lazyClient().Wait(ctx)
+}
 
-	if _, err := c.ListWorkers(ctx); err != nil {
+func (d *Driver) Info(ctx context.Context) (*driver.Info, error) {
+	if _, err := d.lazyClient().ListWorkers(ctx); err != nil {
+		d.log(ctx).WithError(err).Debug("remote driver inactive")
 		return &driver.Info{
 			Status: driver.Inactive,
 		}, nil
@@ -52,6 +70,15 @@ func (d *Driver) Info(ctx context.Context) (*driver.Info, error) {
 	}, nil
 }
 
+// log returns a logger tagged with fields identifying this driver instance.
+func (d *Driver) log(ctx context.Context) *logrus.Entry {
+	return bklog.G(ctx).WithFields(logrus.Fields{
+		"driver":   "remote",
+		"endpoint": d.InitConfig.EndpointAddr,
+		"builder":  d.InitConfig.Name,
+	})
+}
+
 func (d *Driver) Version(ctx context.Context) (string, error) {
 	return "", nil
 }
@@ -64,7 +91,23 @@ func (d *Driver) Rm(ctx context.Context, force, rmVolume, rmDaemon bool) error {
 	return nil
 }
 
+// Client returns the current buildkit client, re-dialing first if a cheap
+// liveness probe shows the cached connection is dead. It can't protect
+// whatever RPC a caller goes on to make with the result (e.g. Solve)
+// against a daemon restart mid-call.
 func (d *Driver) Client(ctx context.Context) (*client.Client, error) {
+	return d.lazyClient().Client(ctx)
+}
+
+func (d *Driver) lazyClient() *LazyClient {
+	d.lazyOnce.Do(func() {
+		d.lazy = NewLazyClient(d.dial)
+	})
+	return d.lazy
+}
+
+func (d *Driver) dial(ctx context.Context) (*client.Client, error) {
+	d.log(ctx).Debug("dialing remote buildkit")
 	opts := []client.ClientOpt{}
 
 	backoffConfig := backoff.DefaultConfig
@@ -82,13 +125,22 @@ func (d *Driver) Client(ctx context.Context) (*client.Client, error) {
 	}
 
 	if d.tlsOpts != nil {
-		opts = append(opts, []client.ClientOpt{
-			client.WithServerConfig(d.tlsOpts.serverName, d.tlsOpts.caCert),
-			client.WithCredentials(d.tlsOpts.cert, d.tlsOpts.key),
-		}...)
+		creds, err := newReloadingTransportCredentials(d.tlsOpts.serverName, d.tlsOpts.caCert, d.tlsOpts.cert, d.tlsOpts.key, d.tlsOpts.reloadInterval)
+		if err != nil {
+			return nil, errors.Wrap(err, "failed to set up reloading TLS credentials")
+		}
+		opts = append(opts, client.WithGRPCDialOption(grpc.WithTransportCredentials(creds)))
+	}
+
+	if d.ClientOpts != nil {
+		opts = append(opts, d.ClientOpts()...)
 	}
 
-	return client.New(ctx, d.InitConfig.EndpointAddr, opts...)
+	c, err := client.New(ctx, d.InitConfig.EndpointAddr, opts...)
+	if err != nil {
+		d.log(ctx).WithError(err).Debug("failed to dial remote buildkit")
+	}
+	return c, err
 }
 
 func (d *Driver) Features(ctx context.Context) map[driver.Feature]bool {
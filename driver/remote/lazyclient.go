@@ -0,0 +1,198 @@
+package remote
+
+import (
+	"context"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	lazyClientBackoffBase = 250 * time.Millisecond
+	lazyClientBackoffCap  = 5 * time.Second
+	lazyClientMaxRetries  = 5
+)
+
+// LazyClient dials a *client.Client against a remote buildkitd lazily and
+// transparently re-dials it, with backoff, once it detects the connection
+// is gone.
+type LazyClient struct {
+	dial func(ctx context.Context) (*client.Client, error)
+
+	mu      sync.Mutex
+	cur     *client.Client
+	dialing bool
+	ready   chan struct{}
+	dialErr error
+}
+
+func NewLazyClient(dial func(ctx context.Context) (*client.Client, error)) *LazyClient {
+	return &LazyClient{dial: dial}
+}
+
+// Get returns the current client, dialing one if none exists yet. Unlike
+// Client, it doesn't check whether an existing connection is still alive.
+func (l *LazyClient) Get(ctx context.Context) (*client.Client, error) {
+	l.mu.Lock()
+	c := l.cur
+	l.mu.Unlock()
+	if c != nil {
+		return c, nil
+	}
+	return l.redial(ctx)
+}
+
+// Client is Get plus a cheap ListWorkers probe to catch a dead connection
+// before handing it back; RPCs a caller goes on to make with the result
+// (e.g. Solve) aren't themselves wrapped and won't retry on their own.
+func (l *LazyClient) Client(ctx context.Context) (*client.Client, error) {
+	if _, err := l.Get(ctx); err != nil {
+		return nil, err
+	}
+	if err := l.call(ctx, func(c *client.Client) error {
+		_, err := c.ListWorkers(ctx)
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	return l.cur, nil
+}
+
+// redial ensures only one goroutine dials at a time: the first caller runs
+// dialWithBackoff while later callers wait on ready and reuse its result.
+func (l *LazyClient) redial(ctx context.Context) (*client.Client, error) {
+	l.mu.Lock()
+	if l.dialing {
+		ready := l.ready
+		l.mu.Unlock()
+		select {
+		case <-ready:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+		l.mu.Lock()
+		c, err := l.cur, l.dialErr
+		l.mu.Unlock()
+		return c, err
+	}
+
+	l.dialing = true
+	l.ready = make(chan struct{})
+	if l.cur != nil {
+		l.cur.Close()
+		l.cur = nil
+	}
+	l.mu.Unlock()
+
+	c, err := l.dialWithBackoff(ctx)
+
+	l.mu.Lock()
+	l.cur, l.dialErr = c, err
+	l.dialing = false
+	close(l.ready)
+	l.mu.Unlock()
+
+	return c, err
+}
+
+func (l *LazyClient) dialWithBackoff(ctx context.Context) (*client.Client, error) {
+	delay := lazyClientBackoffBase
+	var err error
+	for attempt := 0; attempt < lazyClientMaxRetries; attempt++ {
+		if attempt > 0 {
+			select {
+			case <-time.After(delay):
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			}
+			delay *= 2
+			if delay > lazyClientBackoffCap {
+				delay = lazyClientBackoffCap
+			}
+		}
+
+		var c *client.Client
+		c, err = l.dial(ctx)
+		if err == nil {
+			return c, nil
+		}
+	}
+	return nil, errors.Wrap(err, "failed to re-dial buildkit client")
+}
+
+// call runs fn against the current client, and if fn fails because the
+// transport went away, re-dials once and retries fn against the fresh
+// client before giving up.
+func (l *LazyClient) call(ctx context.Context, fn func(*client.Client) error) error {
+	c, err := l.Get(ctx)
+	if err != nil {
+		return err
+	}
+
+	err = fn(c)
+	if !isTransportDown(err) {
+		return err
+	}
+
+	c, derr := l.redial(ctx)
+	if derr != nil {
+		return err
+	}
+	return fn(c)
+}
+
+func isTransportDown(err error) bool {
+	if err == nil {
+		return false
+	}
+	if errors.Is(err, io.EOF) {
+		return true
+	}
+	switch status.Code(err) {
+	case codes.Unavailable, codes.Canceled:
+		return true
+	}
+	return false
+}
+
+// Wait proxies client.Client.Wait, re-dialing and retrying once on a dead
+// transport.
+func (l *LazyClient) Wait(ctx context.Context) error {
+	return l.call(ctx, func(c *client.Client) error {
+		return c.Wait(ctx)
+	})
+}
+
+// ListWorkers proxies client.Client.ListWorkers, re-dialing and retrying
+// once on a dead transport.
+func (l *LazyClient) ListWorkers(ctx context.Context, opts ...client.ListWorkersOption) (*client.ListWorkersResponse, error) {
+	var resp *client.ListWorkersResponse
+	err := l.call(ctx, func(c *client.Client) error {
+		r, err := c.ListWorkers(ctx, opts...)
+		if err != nil {
+			return err
+		}
+		resp = r
+		return nil
+	})
+	return resp, err
+}
+
+// Close releases the underlying client, if any.
+func (l *LazyClient) Close() error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.cur == nil {
+		return nil
+	}
+	err := l.cur.Close()
+	l.cur = nil
+	return err
+}
@@ -0,0 +1,74 @@
+package remote
+
+import (
+	"context"
+	"io"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestIsTransportDown(t *testing.T) {
+	require.False(t, isTransportDown(nil))
+	require.True(t, isTransportDown(io.EOF))
+	require.True(t, isTransportDown(status.Error(codes.Unavailable, "unavailable")))
+	require.True(t, isTransportDown(status.Error(codes.Canceled, "canceled")))
+	require.False(t, isTransportDown(status.Error(codes.NotFound, "not found")))
+}
+
+// TestLazyClientRedialIsSingleFlight guards against concurrent callers each
+// running their own dial-with-backoff sequence: only the first should
+// actually dial, the rest should wait on its result.
+func TestLazyClientRedialIsSingleFlight(t *testing.T) {
+	var dials int32
+	dial := func(ctx context.Context) (*client.Client, error) {
+		atomic.AddInt32(&dials, 1)
+		time.Sleep(20 * time.Millisecond)
+		return &client.Client{}, nil
+	}
+	l := NewLazyClient(dial)
+
+	const n = 10
+	var wg sync.WaitGroup
+	errs := make([]error, n)
+	wg.Add(n)
+	for i := 0; i < n; i++ {
+		go func(i int) {
+			defer wg.Done()
+			_, errs[i] = l.redial(context.Background())
+		}(i)
+	}
+	wg.Wait()
+
+	for _, err := range errs {
+		require.NoError(t, err)
+	}
+	require.EqualValues(t, 1, atomic.LoadInt32(&dials))
+}
+
+// TestLazyClientDialWithBackoffRetries verifies the backoff loop retries a
+// failing dial, up to lazyClientMaxRetries attempts in all, with increasing
+// delays, rather than giving up on (or hammering) the first failure.
+func TestLazyClientDialWithBackoffRetries(t *testing.T) {
+	var attempts int32
+	dial := func(ctx context.Context) (*client.Client, error) {
+		if atomic.AddInt32(&attempts, 1) < 3 {
+			return nil, status.Error(codes.Unavailable, "not ready yet")
+		}
+		return &client.Client{}, nil
+	}
+	l := NewLazyClient(dial)
+
+	start := time.Now()
+	c, err := l.Get(context.Background())
+	require.NoError(t, err)
+	require.NotNil(t, c)
+	require.EqualValues(t, 3, atomic.LoadInt32(&attempts))
+	require.GreaterOrEqual(t, time.Since(start), lazyClientBackoffBase+2*lazyClientBackoffBase)
+}
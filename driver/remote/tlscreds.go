@@ -0,0 +1,136 @@
+package remote
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"net"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"google.golang.org/grpc/credentials"
+)
+
+// reloadingTransportCredentials is a credentials.TransportCredentials that
+// re-reads the CA/cert/key PEM files from disk instead of loading them once
+// at dial time, so long-lived sessions pick up a rotated cert without
+// getting stuck on a stale chain.
+//
+// reloadInterval bounds how often the files are actually re-read: zero
+// re-reads on every handshake, a positive value reuses the last loaded
+// tls.Config until it expires.
+type reloadingTransportCredentials struct {
+	serverName     string
+	caCert         string
+	cert           string
+	key            string
+	reloadInterval time.Duration
+
+	mu       sync.Mutex
+	cfg      *tls.Config
+	loadedAt time.Time
+}
+
+func newReloadingTransportCredentials(serverName, caCert, cert, key string, reloadInterval time.Duration) (*reloadingTransportCredentials, error) {
+	c := &reloadingTransportCredentials{
+		serverName:     serverName,
+		caCert:         caCert,
+		cert:           cert,
+		key:            key,
+		reloadInterval: reloadInterval,
+	}
+	if _, err := c.current(); err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+func (c *reloadingTransportCredentials) current() (*tls.Config, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if c.cfg != nil && c.reloadInterval > 0 && time.Since(c.loadedAt) < c.reloadInterval {
+		return c.cfg, nil
+	}
+
+	cfg, err := loadTLSConfig(c.serverName, c.caCert, c.cert, c.key)
+	if err != nil {
+		if c.cfg != nil {
+			// Keep serving the last known-good config: a rotation in
+			// progress (partially written files) shouldn't break an
+			// otherwise healthy long-lived session.
+			return c.cfg, nil
+		}
+		return nil, err
+	}
+
+	c.cfg, c.loadedAt = cfg, time.Now()
+	return c.cfg, nil
+}
+
+func loadTLSConfig(serverName, caCert, cert, key string) (*tls.Config, error) {
+	caPEM, err := os.ReadFile(caCert)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to read ca cert")
+	}
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(caPEM) {
+		return nil, errors.Errorf("failed to parse ca cert %s", caCert)
+	}
+
+	keyPair, err := tls.LoadX509KeyPair(cert, key)
+	if err != nil {
+		return nil, errors.Wrap(err, "failed to load client cert/key")
+	}
+
+	return &tls.Config{
+		ServerName:   serverName,
+		RootCAs:      pool,
+		Certificates: []tls.Certificate{keyPair},
+	}, nil
+}
+
+func (c *reloadingTransportCredentials) ClientHandshake(ctx context.Context, authority string, rawConn net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	cfg, err := c.current()
+	if err != nil {
+		return nil, nil, err
+	}
+	return credentials.NewTLS(cfg).ClientHandshake(ctx, authority, rawConn)
+}
+
+func (c *reloadingTransportCredentials) ServerHandshake(net.Conn) (net.Conn, credentials.AuthInfo, error) {
+	return nil, nil, errors.New("reloadingTransportCredentials is client-only")
+}
+
+func (c *reloadingTransportCredentials) Info() credentials.ProtocolInfo {
+	cfg, err := c.current()
+	if err != nil {
+		return credentials.ProtocolInfo{SecurityProtocol: "tls"}
+	}
+	return credentials.NewTLS(cfg).Info()
+}
+
+func (c *reloadingTransportCredentials) Clone() credentials.TransportCredentials {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	n := &reloadingTransportCredentials{
+		serverName:     c.serverName,
+		caCert:         c.caCert,
+		cert:           c.cert,
+		key:            c.key,
+		reloadInterval: c.reloadInterval,
+		cfg:            c.cfg,
+		loadedAt:       c.loadedAt,
+	}
+	return n
+}
+
+func (c *reloadingTransportCredentials) OverrideServerName(name string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.serverName = name
+	c.cfg = nil
+	return nil
+}
@@ -0,0 +1,155 @@
+package remote
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/require"
+)
+
+func genCA(t *testing.T) (*x509.Certificate, []byte, *ecdsa.PrivateKey) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "test-ca"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		KeyUsage:              x509.KeyUsageCertSign | x509.KeyUsageDigitalSignature,
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+	}
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, tmpl, &key.PublicKey, key)
+	require.NoError(t, err)
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return cert, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der}), key
+}
+
+// genLeaf issues a cert signed by ca/caKey; isServer controls the
+// ExtKeyUsage so the same helper can mint both ends of the handshake.
+func genLeaf(t *testing.T, ca *x509.Certificate, caKey *ecdsa.PrivateKey, cn string, isServer bool) ([]byte, []byte) {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	tmpl := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: cn},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature,
+	}
+	if isServer {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth}
+		tmpl.DNSNames = []string{cn}
+		tmpl.IPAddresses = []net.IP{net.ParseIP("127.0.0.1")}
+	} else {
+		tmpl.ExtKeyUsage = []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, tmpl, ca, &key.PublicKey, caKey)
+	require.NoError(t, err)
+	keyDER, err := x509.MarshalECPrivateKey(key)
+	require.NoError(t, err)
+
+	certPEM := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: der})
+	keyPEM := pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: keyDER})
+	return certPEM, keyPEM
+}
+
+// TestReloadingTransportCredentialsRotation rotates the client cert/key
+// files on disk and verifies a subsequent handshake picks up the new
+// identity instead of the one loaded at construction time.
+func TestReloadingTransportCredentialsRotation(t *testing.T) {
+	ca, caCertPEM, caKey := genCA(t)
+
+	serverCertPEM, serverKeyPEM := genLeaf(t, ca, caKey, "test-server", true)
+	serverCert, err := tls.X509KeyPair(serverCertPEM, serverKeyPEM)
+	require.NoError(t, err)
+
+	caPool := x509.NewCertPool()
+	require.True(t, caPool.AppendCertsFromPEM(caCertPEM))
+
+	ln, err := tls.Listen("tcp", "127.0.0.1:0", &tls.Config{
+		Certificates: []tls.Certificate{serverCert},
+		ClientAuth:   tls.RequireAndVerifyClientCert,
+		ClientCAs:    caPool,
+	})
+	require.NoError(t, err)
+	defer ln.Close()
+
+	seenCN := make(chan string, 1)
+	go func() {
+		for {
+			conn, err := ln.Accept()
+			if err != nil {
+				return
+			}
+			go func(tc *tls.Conn) {
+				defer tc.Close()
+				if err := tc.Handshake(); err != nil {
+					return
+				}
+				cn := ""
+				if peers := tc.ConnectionState().PeerCertificates; len(peers) > 0 {
+					cn = peers[0].Subject.CommonName
+				}
+				seenCN <- cn
+			}(conn.(*tls.Conn))
+		}
+	}()
+
+	dir := t.TempDir()
+	caPath := filepath.Join(dir, "ca.pem")
+	certPath := filepath.Join(dir, "cert.pem")
+	keyPath := filepath.Join(dir, "key.pem")
+	require.NoError(t, os.WriteFile(caPath, caCertPEM, 0o600))
+
+	clientV1CertPEM, clientV1KeyPEM := genLeaf(t, ca, caKey, "client-v1", false)
+	require.NoError(t, os.WriteFile(certPath, clientV1CertPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyPath, clientV1KeyPEM, 0o600))
+
+	creds, err := newReloadingTransportCredentials("test-server", caPath, certPath, keyPath, 0)
+	require.NoError(t, err)
+
+	handshake := func() string {
+		raw, err := net.Dial("tcp", ln.Addr().String())
+		require.NoError(t, err)
+		defer raw.Close()
+		conn, _, err := creds.ClientHandshake(context.Background(), ln.Addr().String(), raw)
+		require.NoError(t, err)
+		defer conn.Close()
+
+		select {
+		case cn := <-seenCN:
+			return cn
+		case <-time.After(5 * time.Second):
+			t.Fatal("timed out waiting for server to observe the handshake")
+			return ""
+		}
+	}
+
+	require.Equal(t, "client-v1", handshake())
+
+	clientV2CertPEM, clientV2KeyPEM := genLeaf(t, ca, caKey, "client-v2", false)
+	require.NoError(t, os.WriteFile(certPath, clientV2CertPEM, 0o600))
+	require.NoError(t, os.WriteFile(keyPath, clientV2KeyPEM, 0o600))
+
+	require.Equal(t, "client-v2", handshake())
+}
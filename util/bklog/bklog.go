@@ -0,0 +1,29 @@
+// Package bklog provides a context-attached logrus logger, following the
+// G(ctx)/L/WithLogger pattern BuildKit and containerd use internally.
+package bklog
+
+import (
+	"context"
+
+	"github.com/sirupsen/logrus"
+)
+
+type loggerKey struct{}
+
+// L is the default logger used by G when ctx carries none.
+var L = logrus.NewEntry(logrus.StandardLogger())
+
+// WithLogger returns a context derived from ctx carrying logger, for a
+// later G(ctx) call to pick up.
+func WithLogger(ctx context.Context, logger *logrus.Entry) context.Context {
+	return context.WithValue(ctx, loggerKey{}, logger)
+}
+
+// G returns the logger attached to ctx via WithLogger, or L if none was
+// attached.
+func G(ctx context.Context) *logrus.Entry {
+	if logger, ok := ctx.Value(loggerKey{}).(*logrus.Entry); ok {
+		return logger
+	}
+	return L
+}
@@ -0,0 +1,135 @@
+package progress
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	"github.com/moby/buildkit/solver/pb"
+	digest "github.com/opencontainers/go-digest"
+)
+
+// defaultJSONSchemaVersion is used when the caller doesn't pass
+// WithJSONSchemaVersion.
+const defaultJSONSchemaVersion = "1"
+
+// jsonEvent is the newline-delimited JSON representation of a single
+// client.SolveStatus, emitted by PrinterModeJSON and PrinterModeRawJSON.
+type jsonEvent struct {
+	SchemaVersion string                 `json:"schemaVersion"`
+	Vertexes      []*jsonVertex          `json:"vertexes,omitempty"`
+	Statuses      []*client.VertexStatus `json:"statuses,omitempty"`
+	Logs          []*jsonVertexLog       `json:"logs,omitempty"`
+	Warnings      []*jsonVertexWarning   `json:"warnings,omitempty"`
+}
+
+type jsonVertex struct {
+	Digest    digest.Digest   `json:"digest"`
+	Name      string          `json:"name"`
+	Inputs    []digest.Digest `json:"inputs,omitempty"`
+	Cached    bool            `json:"cached,omitempty"`
+	Started   *time.Time      `json:"started,omitempty"`
+	Completed *time.Time      `json:"completed,omitempty"`
+	Error     string          `json:"error,omitempty"`
+}
+
+type jsonVertexLog struct {
+	Vertex    digest.Digest `json:"vertex"`
+	Stream    int           `json:"stream"`
+	Data      interface{}   `json:"data"`
+	Timestamp time.Time     `json:"timestamp"`
+}
+
+type jsonVertexWarning struct {
+	Vertex digest.Digest `json:"vertex"`
+	Level  int           `json:"level"`
+	Short  string        `json:"short"`
+	Detail []string      `json:"detail,omitempty"`
+	URL    string        `json:"url,omitempty"`
+	Range  []*pb.Range   `json:"range,omitempty"`
+}
+
+// newJSONEvent converts a client.SolveStatus into its JSON representation.
+// In raw mode, log data is kept as []byte (base64-encoded by
+// encoding/json); otherwise it's decoded to a utf-8 string.
+func newJSONEvent(ss *client.SolveStatus, schemaVersion string, raw bool) *jsonEvent {
+	ev := &jsonEvent{SchemaVersion: schemaVersion}
+
+	for _, v := range ss.Vertexes {
+		ev.Vertexes = append(ev.Vertexes, &jsonVertex{
+			Digest:    v.Digest,
+			Name:      v.Name,
+			Inputs:    v.Inputs,
+			Cached:    v.Cached,
+			Started:   v.Started,
+			Completed: v.Completed,
+			Error:     v.Error,
+		})
+	}
+
+	ev.Statuses = ss.Statuses
+
+	for _, l := range ss.Logs {
+		jl := &jsonVertexLog{
+			Vertex:    l.Vertex,
+			Stream:    l.Stream,
+			Timestamp: l.Timestamp,
+		}
+		if raw {
+			jl.Data = l.Data
+		} else {
+			jl.Data = string(l.Data)
+		}
+		ev.Logs = append(ev.Logs, jl)
+	}
+
+	for _, w := range ss.Warnings {
+		ev.Warnings = append(ev.Warnings, &jsonVertexWarning{
+			Vertex: w.Vertex,
+			Level:  w.Level,
+			Short:  string(w.Short),
+			Detail: byteSlicesToStrings(w.Detail),
+			URL:    w.URL,
+			Range:  w.Range,
+		})
+	}
+
+	return ev
+}
+
+func byteSlicesToStrings(in [][]byte) []string {
+	if len(in) == 0 {
+		return nil
+	}
+	out := make([]string, len(in))
+	for i, b := range in {
+		out[i] = string(b)
+	}
+	return out
+}
+
+// displayJSON drains statusCh, writing one JSON object per line to w until
+// the channel is closed or ctx is done. It returns the same values as
+// progressui.DisplaySolveStatus so display can select between the two.
+func displayJSON(ctx context.Context, w io.Writer, statusCh chan *client.SolveStatus, schemaVersion string, raw bool) ([]client.VertexWarning, error) {
+	enc := json.NewEncoder(w)
+	var warnings []client.VertexWarning
+	for {
+		select {
+		case ss, ok := <-statusCh:
+			if !ok {
+				return warnings, nil
+			}
+			for _, w := range ss.Warnings {
+				warnings = append(warnings, *w)
+			}
+			if err := enc.Encode(newJSONEvent(ss, schemaVersion, raw)); err != nil {
+				return warnings, err
+			}
+		case <-ctx.Done():
+			return warnings, ctx.Err()
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/moby/buildkit/client"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDisplayJSONEmitsOneSchemaVersionedEventPerStatus(t *testing.T) {
+	ch := make(chan *client.SolveStatus, 2)
+	ch <- &client.SolveStatus{Vertexes: []*client.Vertex{{Digest: digest.Digest("sha256:aaa"), Name: "step1"}}}
+	ch <- &client.SolveStatus{Vertexes: []*client.Vertex{{Digest: digest.Digest("sha256:bbb"), Name: "step2"}}}
+	close(ch)
+
+	var buf bytes.Buffer
+	warnings, err := displayJSON(context.Background(), &buf, ch, "42", false)
+	require.NoError(t, err)
+	require.Empty(t, warnings)
+
+	dec := json.NewDecoder(&buf)
+	var events []jsonEvent
+	for dec.More() {
+		var ev jsonEvent
+		require.NoError(t, dec.Decode(&ev))
+		events = append(events, ev)
+	}
+
+	require.Len(t, events, 2)
+	for _, ev := range events {
+		require.Equal(t, "42", ev.SchemaVersion)
+	}
+	require.Equal(t, "step1", events[0].Vertexes[0].Name)
+	require.Equal(t, "step2", events[1].Vertexes[0].Name)
+}
+
+func TestWithJSONSchemaVersionOverridesDefault(t *testing.T) {
+	opt := &printerOpts{}
+	WithJSONSchemaVersion("7")(opt)
+	require.Equal(t, "7", opt.jsonSchemaVersion)
+
+	ev := newJSONEvent(&client.SolveStatus{}, opt.jsonSchemaVersion, false)
+	require.Equal(t, "7", ev.SchemaVersion)
+}
@@ -0,0 +1,24 @@
+package progress
+
+import (
+	"context"
+
+	"github.com/docker/buildx/util/bklog"
+	"github.com/moby/buildkit/client"
+)
+
+// logVertices emits one structured log line per vertex transition seen on
+// the status channel, tagged with a vertex field.
+func (p *Printer) logVertices(ctx context.Context, ss *client.SolveStatus) {
+	for _, v := range ss.Vertexes {
+		log := bklog.G(ctx).WithField("vertex", v.Digest.String())
+		switch {
+		case v.Completed != nil && v.Error != "":
+			log.WithField("error", v.Error).Debug("vertex errored")
+		case v.Completed != nil:
+			log.WithField("cached", v.Cached).Debug("vertex completed")
+		case v.Started != nil:
+			log.Debug("vertex started")
+		}
+	}
+}
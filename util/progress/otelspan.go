@@ -0,0 +1,80 @@
+package progress
+
+import (
+	"context"
+
+	"github.com/moby/buildkit/client"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/docker/buildx/util/progress"
+
+// resolveTracer returns tp's tracer, falling back to the global
+// TracerProvider when tp is unset.
+func resolveTracer(tp trace.TracerProvider) trace.Tracer {
+	if tp == nil {
+		tp = otel.GetTracerProvider()
+	}
+	return tp.Tracer(tracerName)
+}
+
+// recordSpans opens one span per vertex the first time it's seen with a
+// Started timestamp, with its start time taken from the vertex itself, and
+// closes it once a Completed
+// timestamp arrives for that digest. The span map lives on the Printer, not
+// per display session, so Pause/Unpause never re-opens a span already
+// tracked. Spans are started from ctx so they nest under whatever span is
+// already live on it, instead of each becoming its own trace root.
+func (p *Printer) recordSpans(ctx context.Context, ss *client.SolveStatus) {
+	p.spanMu.Lock()
+	defer p.spanMu.Unlock()
+
+	for _, v := range ss.Vertexes {
+		span, ok := p.spans[v.Digest]
+		if !ok {
+			if v.Started == nil {
+				continue
+			}
+			_, span = p.tracer.Start(ctx, v.Name, trace.WithTimestamp(*v.Started))
+			span.SetAttributes(attribute.String("vertex.digest", v.Digest.String()))
+			if len(v.Inputs) > 0 {
+				inputs := make([]string, len(v.Inputs))
+				for i, in := range v.Inputs {
+					inputs[i] = in.String()
+				}
+				span.SetAttributes(attribute.StringSlice("vertex.inputs", inputs))
+			}
+			p.spans[v.Digest] = span
+		}
+
+		if v.Completed == nil {
+			continue
+		}
+		span.SetAttributes(attribute.Bool("vertex.cached", v.Cached))
+		if v.Error != "" {
+			span.SetAttributes(attribute.String("vertex.error", v.Error))
+			span.SetStatus(codes.Error, v.Error)
+		}
+		span.End(trace.WithTimestamp(*v.Completed))
+		delete(p.spans, v.Digest)
+	}
+}
+
+// closeOpenSpans ends any span still tracked when the Printer tears down
+// for good (Wait, not Pause) without ever seeing that vertex's Completed
+// timestamp, e.g. because the build errored or was cancelled. Without this
+// those spans would never call End and most span processors would just
+// drop them.
+func (p *Printer) closeOpenSpans() {
+	p.spanMu.Lock()
+	defer p.spanMu.Unlock()
+
+	for d, span := range p.spans {
+		span.SetStatus(codes.Error, "build did not complete")
+		span.End()
+		delete(p.spans, d)
+	}
+}
@@ -0,0 +1,67 @@
+package progress
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/moby/buildkit/client"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+	"go.opentelemetry.io/otel/codes"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newTestPrinter(t *testing.T) (*Printer, *tracetest.InMemoryExporter) {
+	t.Helper()
+	exp := tracetest.NewInMemoryExporter()
+	tp := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exp))
+	return &Printer{
+		tracer: resolveTracer(tp),
+		spans:  map[digest.Digest]trace.Span{},
+	}, exp
+}
+
+func TestCloseOpenSpansEndsUnfinishedSpans(t *testing.T) {
+	p, exp := newTestPrinter(t)
+
+	started := time.Now()
+	p.recordSpans(context.Background(), &client.SolveStatus{
+		Vertexes: []*client.Vertex{{Digest: digest.Digest("sha256:aaa"), Name: "step1", Started: &started}},
+	})
+	require.Len(t, p.spans, 1)
+
+	p.closeOpenSpans()
+	require.Empty(t, p.spans)
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 1)
+	require.Equal(t, codes.Error, spans[0].Status.Code)
+}
+
+// TestRecordSpansEndsCompletedVertexOnce checks that a vertex already ended
+// normally (via a Completed timestamp) isn't re-ended by a later
+// closeOpenSpans call when the Printer is finally torn down.
+func TestRecordSpansEndsCompletedVertexOnce(t *testing.T) {
+	p, exp := newTestPrinter(t)
+
+	started := time.Now()
+	completed := started.Add(time.Second)
+	dgst := digest.Digest("sha256:aaa")
+
+	p.recordSpans(context.Background(), &client.SolveStatus{
+		Vertexes: []*client.Vertex{{Digest: dgst, Name: "step1", Started: &started}},
+	})
+	p.recordSpans(context.Background(), &client.SolveStatus{
+		Vertexes: []*client.Vertex{{Digest: dgst, Name: "step1", Started: &started, Completed: &completed}},
+	})
+	require.Empty(t, p.spans)
+
+	p.closeOpenSpans()
+
+	spans := exp.GetSpans()
+	require.Len(t, spans, 1)
+	require.NotEqual(t, codes.Error, spans[0].Status.Code)
+}
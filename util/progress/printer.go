@@ -7,19 +7,24 @@ import (
 	"sync"
 
 	"github.com/containerd/console"
+	"github.com/docker/buildx/util/bklog"
 	"github.com/docker/buildx/util/logutil"
 	"github.com/moby/buildkit/client"
 	"github.com/moby/buildkit/util/progress/progressui"
 	"github.com/opencontainers/go-digest"
 	"github.com/pkg/errors"
-	"github.com/sirupsen/logrus"
+	"go.opentelemetry.io/otel/trace"
 )
 
+const sinkChannelBuffer = 64
+
 const (
-	PrinterModeAuto  = "auto"
-	PrinterModeTty   = "tty"
-	PrinterModePlain = "plain"
-	PrinterModeQuiet = "quiet"
+	PrinterModeAuto    = "auto"
+	PrinterModeTty     = "tty"
+	PrinterModePlain   = "plain"
+	PrinterModeQuiet   = "quiet"
+	PrinterModeJSON    = "json"
+	PrinterModeRawJSON = "rawjson"
 )
 
 type Printer struct {
@@ -33,6 +38,10 @@ type Printer struct {
 	warnings     []client.VertexWarning
 	logMu        sync.Mutex
 	logSourceMap map[digest.Digest]interface{}
+
+	tracer trace.Tracer
+	spanMu sync.Mutex
+	spans  map[digest.Digest]trace.Span
 }
 
 func (p *Printer) Wait() error {
@@ -89,6 +98,9 @@ func NewPrinter(ctx context.Context, w io.Writer, out console.File, mode string,
 	for _, o := range opts {
 		o(opt)
 	}
+	if opt.jsonSchemaVersion == "" {
+		opt.jsonSchemaVersion = defaultJSONSchemaVersion
+	}
 
 	if v := os.Getenv("BUILDKIT_PROGRESS"); v != "" && mode == PrinterModeAuto {
 		mode = v
@@ -109,7 +121,9 @@ func NewPrinter(ctx context.Context, w io.Writer, out console.File, mode string,
 	}
 
 	pw := &Printer{
-		ready: make(chan struct{}),
+		ready:  make(chan struct{}),
+		tracer: resolveTracer(opt.tracerProvider),
+		spans:  map[digest.Digest]trace.Span{},
 	}
 	go func() {
 		for {
@@ -122,9 +136,12 @@ func NewPrinter(ctx context.Context, w io.Writer, out console.File, mode string,
 
 			close(pw.ready)
 
-			resumeLogs := logutil.Pause(logrus.StandardLogger())
+			resumeLogs := logutil.Pause(bklog.G(ctx).Logger)
 			// not using shared context to not disrupt display but let is finish reporting errors
-			pw.warnings, pw.err = progressui.DisplaySolveStatus(ctx, c, w, pw.status, opt.displayOpts...)
+			pw.warnings, pw.err = runSinks(ctx, c, w, mode, pw.status, opt, func(ss *client.SolveStatus) {
+				pw.recordSpans(ctx, ss)
+				pw.logVertices(ctx, ss)
+			})
 			resumeLogs()
 			close(pw.done)
 
@@ -132,6 +149,7 @@ func NewPrinter(ctx context.Context, w io.Writer, out console.File, mode string,
 				opt.onclose()
 			}
 			if pw.paused == nil {
+				pw.closeOpenSpans()
 				break
 			}
 
@@ -144,10 +162,135 @@ func NewPrinter(ctx context.Context, w io.Writer, out console.File, mode string,
 	return pw, nil
 }
 
+// display runs a single sink's backend over ch, branching on mode the same
+// way the primary target does.
+func display(ctx context.Context, c console.Console, w io.Writer, mode string, ch chan *client.SolveStatus, opt *printerOpts) ([]client.VertexWarning, error) {
+	switch mode {
+	case PrinterModeJSON, PrinterModeRawJSON:
+		return displayJSON(ctx, w, ch, opt.jsonSchemaVersion, mode == PrinterModeRawJSON)
+	case PrinterModeQuiet:
+		return progressui.DisplaySolveStatus(ctx, c, io.Discard, ch, opt.displayOpts...)
+	default:
+		return progressui.DisplaySolveStatus(ctx, c, w, ch, opt.displayOpts...)
+	}
+}
+
+// sinkBroadcaster lets each sink forward statuses to its own display backend
+// at its own pace: a push only appends under a quick lock, so a sink whose
+// forward goroutine is blocked sending to a stalled display never holds up
+// the publisher or any other sink.
+type sinkBroadcaster struct {
+	mu     sync.Mutex
+	cond   *sync.Cond
+	buf    []*client.SolveStatus
+	closed bool
+}
+
+func newSinkBroadcaster() *sinkBroadcaster {
+	b := &sinkBroadcaster{}
+	b.cond = sync.NewCond(&b.mu)
+	return b
+}
+
+func (b *sinkBroadcaster) push(s *client.SolveStatus) {
+	b.mu.Lock()
+	b.buf = append(b.buf, s)
+	b.cond.Broadcast()
+	b.mu.Unlock()
+}
+
+func (b *sinkBroadcaster) close() {
+	b.mu.Lock()
+	b.closed = true
+	b.cond.Broadcast()
+	b.mu.Unlock()
+}
+
+// forward sends every status pushed to b, in order from the first one, on
+// ch. It returns once b is closed and fully drained.
+func (b *sinkBroadcaster) forward(ch chan *client.SolveStatus) {
+	defer close(ch)
+	i := 0
+	for {
+		b.mu.Lock()
+		for i >= len(b.buf) && !b.closed {
+			b.cond.Wait()
+		}
+		if i >= len(b.buf) {
+			b.mu.Unlock()
+			return
+		}
+		s := b.buf[i]
+		i++
+		b.mu.Unlock()
+		ch <- s
+	}
+}
+
+// runSinks fans out every status read from statusCh to the primary target
+// (c, w, mode) plus any sinks added via WithSink, each running its own
+// display backend concurrently, then joins them once statusCh closes.
+// observe, if non-nil, is called with every status before it's fanned out.
+func runSinks(ctx context.Context, c console.Console, w io.Writer, mode string, statusCh chan *client.SolveStatus, opt *printerOpts, observe func(*client.SolveStatus)) ([]client.VertexWarning, error) {
+	b := newSinkBroadcaster()
+
+	chs := make([]chan *client.SolveStatus, 1+len(opt.sinks))
+	for i := range chs {
+		chs[i] = make(chan *client.SolveStatus, sinkChannelBuffer)
+		go b.forward(chs[i])
+	}
+
+	var (
+		wg       sync.WaitGroup
+		mu       sync.Mutex
+		warnings []client.VertexWarning
+		firstErr error
+	)
+
+	run := func(ch chan *client.SolveStatus, c console.Console, w io.Writer, mode string) {
+		defer wg.Done()
+		warn, err := display(ctx, c, w, mode, ch, opt)
+		mu.Lock()
+		warnings = append(warnings, warn...)
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+		mu.Unlock()
+	}
+
+	wg.Add(len(chs))
+	go run(chs[0], c, w, mode)
+	for i, s := range opt.sinks {
+		go run(chs[i+1], nil, s.w, s.mode)
+	}
+
+	for s := range statusCh {
+		if observe != nil {
+			observe(s)
+		}
+		b.push(s)
+	}
+	b.close()
+	wg.Wait()
+
+	return warnings, firstErr
+}
+
 type printerOpts struct {
 	displayOpts []progressui.DisplaySolveStatusOpt
 
 	onclose func()
+
+	jsonSchemaVersion string
+	sinks             []sinkOpt
+
+	tracerProvider trace.TracerProvider
+}
+
+// sinkOpt describes one additional output target added via WithSink.
+type sinkOpt struct {
+	w    io.Writer
+	mode string
 }
 
 type PrinterOpt func(b *printerOpts)
@@ -169,3 +312,31 @@ func WithOnClose(onclose func()) PrinterOpt {
 		opt.onclose = onclose
 	}
 }
+
+// WithJSONSchemaVersion sets the schemaVersion field stamped onto every
+// object emitted in PrinterModeJSON/PrinterModeRawJSON, so consumers can
+// evolve alongside the schema instead of guessing at its shape.
+func WithJSONSchemaVersion(v string) PrinterOpt {
+	return func(opt *printerOpts) {
+		opt.jsonSchemaVersion = v
+	}
+}
+
+// WithSink adds an additional output target that every client.SolveStatus
+// is fanned out to, independently of the primary target passed to
+// NewPrinter. mode is one of the PrinterMode* constants other than
+// PrinterModeTty; a sink added this way has no console to render to.
+func WithSink(w io.Writer, mode string) PrinterOpt {
+	return func(opt *printerOpts) {
+		opt.sinks = append(opt.sinks, sinkOpt{w: w, mode: mode})
+	}
+}
+
+// WithTracer sets the trace.TracerProvider used to emit one OpenTelemetry
+// span per vertex seen on the status channel. The global TracerProvider is
+// used when unset.
+func WithTracer(tp trace.TracerProvider) PrinterOpt {
+	return func(opt *printerOpts) {
+		opt.tracerProvider = tp
+	}
+}
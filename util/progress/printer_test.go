@@ -0,0 +1,70 @@
+package progress
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/moby/buildkit/client"
+	digest "github.com/opencontainers/go-digest"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRunSinksForwardsToAdditionalSinks(t *testing.T) {
+	primary := &bytes.Buffer{}
+	sink := &bytes.Buffer{}
+
+	opt := &printerOpts{jsonSchemaVersion: "1"}
+	WithSink(sink, PrinterModeJSON)(opt)
+
+	statusCh := make(chan *client.SolveStatus, 1)
+	statusCh <- &client.SolveStatus{Vertexes: []*client.Vertex{{Digest: digest.Digest("sha256:aaa"), Name: "step1"}}}
+	close(statusCh)
+
+	observed := 0
+	_, err := runSinks(context.Background(), nil, primary, PrinterModeJSON, statusCh, opt, func(*client.SolveStatus) {
+		observed++
+	})
+	require.NoError(t, err)
+	require.Equal(t, 1, observed)
+	require.Equal(t, primary.String(), sink.String())
+	require.NotEmpty(t, primary.String())
+}
+
+func TestRunSinksAggregatesWarningsAcrossSinks(t *testing.T) {
+	primary := &bytes.Buffer{}
+	sink := &bytes.Buffer{}
+
+	opt := &printerOpts{jsonSchemaVersion: "1"}
+	WithSink(sink, PrinterModeJSON)(opt)
+
+	statusCh := make(chan *client.SolveStatus, 1)
+	statusCh <- &client.SolveStatus{Warnings: []*client.VertexWarning{
+		{Vertex: digest.Digest("sha256:aaa"), Short: []byte("warn1")},
+	}}
+	close(statusCh)
+
+	warnings, err := runSinks(context.Background(), nil, primary, PrinterModeJSON, statusCh, opt, nil)
+	require.NoError(t, err)
+	require.Len(t, warnings, 2) // one observation per display backend (primary + the one sink)
+}
+
+type errWriter struct{ err error }
+
+func (w *errWriter) Write([]byte) (int, error) { return 0, w.err }
+
+func TestRunSinksAggregatesErrorsAcrossSinks(t *testing.T) {
+	primary := &bytes.Buffer{}
+	boom := errors.New("boom")
+
+	opt := &printerOpts{jsonSchemaVersion: "1"}
+	WithSink(&errWriter{err: boom}, PrinterModeJSON)(opt)
+
+	statusCh := make(chan *client.SolveStatus, 1)
+	statusCh <- &client.SolveStatus{Vertexes: []*client.Vertex{{Digest: digest.Digest("sha256:aaa"), Name: "step1"}}}
+	close(statusCh)
+
+	_, err := runSinks(context.Background(), nil, primary, PrinterModeJSON, statusCh, opt, nil)
+	require.ErrorIs(t, err, boom)
+}